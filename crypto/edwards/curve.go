@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"crypto/cipher"
 	"dissent/crypto"
+	"dissent/crypto/field25519"
 )
 
 var zero = big.NewInt(0)
@@ -44,6 +45,8 @@ type curve struct {
 	null crypto.Point	// Identity point for this group
 
 	hide hiding		// Uniform point encoding method
+
+	fastD *field25519.Element // cached field25519 encoding of d, lazily set by fastSolveForX
 }
 
 func (c *curve) PrimeOrder() bool {
@@ -142,10 +145,16 @@ func (c *curve) init(self crypto.Group, p *Param, fullGroup bool,
 	// only useful when using the full group.
 	// (Points taken from the subgroup would be trivially recognizable.)
 	if fullGroup {
-		if p.Elligator1s.Sign() != 0 {
-			c.hide = new(el1param).init(c, &p.Elligator1s)
+		// Elligator 1 is not implemented in this package: it requires
+		// a square and (a-d) non-square, a disjoint precondition from
+		// Elligator 2's, so we'd need a second non-square parameter
+		// in Param to select it. Only Elligator 2 is wired up here.
+		if p.Elligator2u.Sign() != 0 {
+			c.hide = new(el2param).init(c, &p.Elligator2u)
+		}
+		if p.ElligatorSqM && c.hide != nil {
+			c.hide = new(elsqparam).init(c, c.hide)
 		}
-		// XXX Elligator2, Squared
 	}
 
 	// Sanity checks
@@ -258,6 +267,10 @@ func reverse(dst,src []byte) []byte {
 // false if there is no x-coordinate corresponding to the chosen y-coordinate.
 //
 func (c *curve) solveForX(x,y *crypto.ModInt) bool {
+	if c.hasFastSqrt() {
+		return c.fastSolveForX(x, y)
+	}
+
 	var yy,t1,t2 crypto.ModInt
 
 	yy.Mul(y,y)				// yy = y^2
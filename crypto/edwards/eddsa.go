@@ -0,0 +1,239 @@
+package edwards
+
+import (
+	"crypto/cipher"
+	"crypto/sha512"
+	"errors"
+
+	"golang.org/x/crypto/sha3"
+
+	"dissent/crypto"
+)
+
+// SignerOptions selects among the "pure", "ctx" and "ph" (prehash)
+// variants of EdDSA defined by RFC 8032. The zero value selects the
+// "pure" variant (no context, no prehashing), matching plain
+// Ed25519/Ed448 signatures as originally specified.
+type SignerOptions struct {
+	// Context is an optional, at most 255-byte domain-separation
+	// string. A non-empty Context (or PreHash == true) selects the
+	// "ctx"/"ph" variant and its RFC 8032 dom2/dom4 prefix (Ed448
+	// hashes a dom4 prefix in the "pure" variant too).
+	Context []byte
+
+	// PreHash selects the "ph" variant: msg is taken to already be
+	// PH(M) (SHA-512 for Ed25519ph, SHAKE256 for Ed448ph) rather
+	// than the message itself.
+	PreHash bool
+}
+
+// PrivateKey is an expanded EdDSA private key: the clamped scalar s
+// and nonce-derivation prefix derived from a random seed per
+// RFC 8032 section 5.1.5 (Ed25519) / 5.2.5 (Ed448), together with
+// the curve it was generated on and the corresponding public key.
+type PrivateKey struct {
+	c      *curve
+	seed   []byte
+	s      crypto.ModInt
+	prefix []byte
+	pub    *PublicKey
+}
+
+// PublicKey is an EdDSA public key: the curve point A = s*B,
+// kept in both point and RFC 8032 encoded form.
+type PublicKey struct {
+	c   *curve
+	A   crypto.Point
+	enc []byte
+}
+
+// GenerateKey creates a new EdDSA keypair on group.
+// Follows RFC 8032: a random seed is expanded via the curve's hash
+// function into (s, prefix), s is clamped into the curve's scalar
+// range, and the public key A = s*B is derived from it.
+func GenerateKey(group crypto.Group, rand cipher.Stream) (*PrivateKey, *PublicKey) {
+	rc, ok := group.(rawCurve)
+	if !ok {
+		panic("eddsa: not an edwards curve group")
+	}
+	c := rc.curveParam()
+
+	seed := make([]byte, c.PointLen())
+	rand.XORKeyStream(seed, seed)
+
+	h := eddsaHash(c, seed, 2*c.PointLen())
+	sb := append([]byte{}, h[:c.PointLen()]...)
+	prefix := h[c.PointLen():]
+	clamp(c, sb)
+
+	var s crypto.ModInt
+	s.M = &c.order.V
+	s.SetBytes(reverseCopy(sb))
+
+	A := c.self.Point()
+	A.Mul(nil, &s)
+	x, y := A.(point).getXY()
+
+	pub := &PublicKey{c: c, A: A, enc: c.encodePoint(x, y)}
+	priv := &PrivateKey{c: c, seed: seed, s: s, prefix: prefix, pub: pub}
+	return priv, pub
+}
+
+// Public returns the public key corresponding to priv.
+func (priv *PrivateKey) Public() *PublicKey {
+	return priv.pub
+}
+
+// Sign signs msg with priv, returning the RFC 8032 signature R||S.
+func (priv *PrivateKey) Sign(msg []byte, opts *SignerOptions) []byte {
+	c := priv.c
+	dom := domPrefix(c, opts)
+
+	rh := eddsaHash(c, concat(dom, priv.prefix, msg), 2*c.PointLen())
+	var r crypto.ModInt
+	r.M = &c.order.V
+	r.SetBytes(reverseCopy(rh))
+
+	R := c.self.Point()
+	R.Mul(nil, &r)
+	Rx, Ry := R.(point).getXY()
+	Renc := c.encodePoint(Rx, Ry)
+
+	kh := eddsaHash(c, concat(dom, Renc, priv.pub.enc, msg), 2*c.PointLen())
+	var k crypto.ModInt
+	k.M = &c.order.V
+	k.SetBytes(reverseCopy(kh))
+
+	var S crypto.ModInt
+	S.M = &c.order.V
+	S.Mul(&k, &priv.s).Add(&S, &r)
+
+	return append(append([]byte{}, Renc...), encodeScalar(c, &S)...)
+}
+
+// Verify reports whether sig is a valid EdDSA signature by pub over msg.
+// It uses the cofactored verification equation [h]S*B == [h]R + [h]k*A
+// recommended by RFC 8032, so signatures remain valid even if R or A
+// happen to carry a small-subgroup component.
+func (pub *PublicKey) Verify(msg []byte, sig []byte, opts *SignerOptions) error {
+	c := pub.c
+	l := c.PointLen()
+	if len(sig) != 2*l {
+		return errors.New("eddsa: signature has wrong length")
+	}
+	Renc, Senc := sig[:l], sig[l:]
+
+	var S crypto.ModInt
+	S.M = &c.order.V
+	S.SetBytes(reverseCopy(Senc))
+
+	var Rx, Ry crypto.ModInt
+	if err := c.decodePoint(Renc, &Rx, &Ry); err != nil {
+		return errors.New("eddsa: invalid signature point R")
+	}
+	R := c.self.Point()
+	R.(point).initXY(&Rx.V, &Ry.V, c.self)
+
+	kh := eddsaHash(c, concat(domPrefix(c, opts), Renc, pub.enc, msg), 2*l)
+	var k crypto.ModInt
+	k.M = &c.order.V
+	k.SetBytes(reverseCopy(kh))
+
+	lhs := c.self.Point()
+	lhs.Mul(nil, &S)
+	lhs.Mul(lhs, &c.cofact)
+
+	rhs := c.self.Point()
+	rhs.Mul(pub.A, &k)
+	rhs.Add(rhs, R)
+	rhs.Mul(rhs, &c.cofact)
+
+	if !lhs.Equal(rhs) {
+		return errors.New("eddsa: signature verification failed")
+	}
+	return nil
+}
+
+// clamp adjusts the scalar encoded (little-endian) in b in place so
+// it always lies in the curve's prime-order subgroup (by clearing
+// the low bits corresponding to the cofactor) and has a fixed bit
+// length, per RFC 8032 5.1.5 (Ed25519) / 5.2.5 (Ed448). The two
+// variants fix the top of the scalar differently, so we switch on
+// c.PointLen() the same way eddsaHash and domPrefix do.
+func clamp(c *curve, b []byte) {
+	clearBits := 0
+	for v := c.R; v > 1; v >>= 1 {
+		clearBits++
+	}
+	for i := 0; i < clearBits; i++ {
+		b[0] &^= 1 << uint(i)
+	}
+	if c.PointLen() <= 32 {
+		// Ed25519 (RFC 8032 5.1.5): clear the high bit of the last
+		// octet and set the next one down.
+		b[len(b)-1] &^= 0x80
+		b[len(b)-1] |= 0x40
+	} else {
+		// Ed448 (RFC 8032 5.2.5): clear the last octet entirely and
+		// set the high bit of the second-to-last octet instead.
+		b[len(b)-1] = 0
+		b[len(b)-2] |= 0x80
+	}
+}
+
+// eddsaHash expands data into an n-byte digest using the curve's
+// domain-appropriate hash: SHA-512 for 32-byte (Ed25519-sized)
+// curves, SHAKE256 otherwise (Ed448-Goldilocks).
+func eddsaHash(c *curve, data []byte, n int) []byte {
+	if c.PointLen() <= 32 {
+		h := sha512.Sum512(data)
+		return h[:n]
+	}
+	out := make([]byte, n)
+	sha3.ShakeSum256(out, data)
+	return out
+}
+
+// domPrefix returns the RFC 8032 dom2/dom4 domain-separation prefix:
+// for Ed25519 only the "ctx"/"ph" variants carry one (nil for "pure"),
+// while Ed448 always hashes dom4, with an empty context for "pure".
+func domPrefix(c *curve, opts *SignerOptions) []byte {
+	if opts == nil || (len(opts.Context) == 0 && !opts.PreHash) {
+		if c.PointLen() <= 32 {
+			return nil
+		}
+		opts = &SignerOptions{}
+	}
+	var phFlag byte
+	if opts.PreHash {
+		phFlag = 1
+	}
+	prefix := "SigEd25519 no Ed25519 collisions"
+	if c.PointLen() > 32 {
+		prefix = "SigEd448"
+	}
+	return concat([]byte(prefix), []byte{phFlag, byte(len(opts.Context))}, opts.Context)
+}
+
+// encodeScalar little-endian-encodes s, padded out to the curve's
+// point length so the R and S halves of a signature line up.
+func encodeScalar(c *curve, s *crypto.ModInt) []byte {
+	b := s.Encode()
+	if len(b) < c.PointLen() {
+		b = append(make([]byte, c.PointLen()-len(b)), b...)
+	}
+	return reverseCopy(b)
+}
+
+// reverseCopy returns a byte-reversed copy of b.
+func reverseCopy(b []byte) []byte {
+	return reverse(make([]byte, len(b)), b)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
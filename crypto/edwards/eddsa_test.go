@@ -0,0 +1,85 @@
+package edwards
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// TestEdDSASignVerify exercises GenerateKey/Sign/Verify end to end on
+// a real curve, including the RFC 8032 "pure" Ed25519 test vector 1
+// (32-byte seed, empty message), to guard against GenerateKey having
+// previously been uncallable outside this package (it took an
+// unexported *curve with no way to obtain one) and against
+// regressions in the signing/verification arithmetic itself.
+func TestEdDSASignVerify(t *testing.T) {
+	p := ed25519TestParam()
+	group := NewExtendedCurve(p, false)
+
+	seed, err := hex.DecodeString("9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60")
+	if err != nil {
+		t.Fatalf("bad seed literal: %v", err)
+	}
+	wantPub, err := hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+	if err != nil {
+		t.Fatalf("bad wantPub literal: %v", err)
+	}
+	wantSig, err := hex.DecodeString("e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b")
+	if err != nil {
+		t.Fatalf("bad wantSig literal: %v", err)
+	}
+
+	priv, pub := GenerateKey(group, &fixedStream{b: seed})
+	if !bytes.Equal(pub.enc, wantPub) {
+		t.Fatalf("public key = %x, want %x", pub.enc, wantPub)
+	}
+
+	sig := priv.Sign(nil, nil)
+	if !bytes.Equal(sig, wantSig) {
+		t.Fatalf("signature = %x, want %x", sig, wantSig)
+	}
+
+	if err := pub.Verify(nil, sig, nil); err != nil {
+		t.Fatalf("Verify of a valid signature failed: %v", err)
+	}
+
+	sig[0] ^= 1
+	if err := pub.Verify(nil, sig, nil); err == nil {
+		t.Fatal("Verify accepted a corrupted signature")
+	}
+}
+
+// TestClamp checks clamp's two RFC 8032 byte layouts directly against
+// a bare *curve carrying just the Param fields clamp reads (R and
+// P, the latter only through PointLen), since neither layout needs a
+// working point backend to exercise.
+func TestClamp(t *testing.T) {
+	// Ed25519 (5.1.5): low 3 bits of b[0] cleared (cofactor 8), high
+	// bit of the last byte cleared, next bit down set.
+	var c25519 curve
+	c25519.Param = *ed25519TestParam()
+	b := bytes.Repeat([]byte{0xff}, c25519.PointLen())
+	clamp(&c25519, b)
+	if b[0]&0x07 != 0 {
+		t.Fatalf("Ed25519 clamp left low cofactor bits set: %#x", b[0])
+	}
+	if b[len(b)-1] != 0x7f {
+		t.Fatalf("Ed25519 clamp produced last byte %#x, want 0x7f", b[len(b)-1])
+	}
+
+	// Ed448 (5.2.5): low 2 bits of b[0] cleared (cofactor 4), last
+	// byte cleared entirely, high bit of the second-to-last byte set.
+	var c448 curve
+	c448.Param = *ed448TestParam()
+	b = bytes.Repeat([]byte{0xff}, c448.PointLen())
+	clamp(&c448, b)
+	if b[0]&0x03 != 0 {
+		t.Fatalf("Ed448 clamp left low cofactor bits set: %#x", b[0])
+	}
+	if b[len(b)-1] != 0 {
+		t.Fatalf("Ed448 clamp left last byte %#x, want 0", b[len(b)-1])
+	}
+	if b[len(b)-2]&0x80 == 0 {
+		t.Fatalf("Ed448 clamp left second-to-last byte high bit clear: %#x", b[len(b)-2])
+	}
+}
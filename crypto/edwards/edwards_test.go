@@ -0,0 +1,66 @@
+package edwards
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"math/big"
+)
+
+// ed25519TestParam returns the standard Ed25519 curve parameters
+// (RFC 8032), so tests exercise this package against a real curve
+// instead of a toy one.
+func ed25519TestParam() *Param {
+	p := &Param{Name: "Ed25519-test", R: 8}
+	setDec(&p.P, "57896044618658097711785492504343953926634992332820282019728792003956564819949")
+	setDec(&p.Q, "7237005577332262213973186563042994240857116359379907606001950938285454250989")
+	setDec(&p.A, "57896044618658097711785492504343953926634992332820282019728792003956564819948") // p-1
+	setDec(&p.D, "37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	setDec(&p.PBX, "15112221349535400772501151409588531511454012693041857206046113283949847762202")
+	setDec(&p.PBY, "46316835694926478169428394003475163141307993866256225615783033603165251855960")
+	setDec(&p.Elligator2u, "2")
+	return p
+}
+
+// ed448TestParam returns a Param sized like Ed448-Goldilocks (448-bit
+// field, cofactor 4, per RFC 8032 5.2.5), but is NOT a validated
+// curve: no backend in this package implements a != -1 curves yet,
+// so this only exists to drive clamp's Ed448-sized byte layout, not
+// for a full sign/verify round trip.
+func ed448TestParam() *Param {
+	p := &Param{Name: "Ed448-test", R: 4}
+	setDec(&p.P, "726838724295606890549323807888004534353641360687318060281490199180612328166730772686396383698676545930088884461843637361053498018365439") // 2^448 - 2^224 - 1
+	return p
+}
+
+func setDec(dst *big.Int, s string) {
+	if _, ok := dst.SetString(s, 10); !ok {
+		panic("edwards: bad test constant " + s)
+	}
+}
+
+// fixedStream is a cipher.Stream that plays back a fixed byte string,
+// so tests can drive Pick/GenerateKey deterministically rather than
+// depending on crypto/rand.
+type fixedStream struct {
+	b []byte
+}
+
+func (s *fixedStream) XORKeyStream(dst, src []byte) {
+	n := len(src)
+	k := s.b[:n]
+	s.b = s.b[n:]
+	for i := 0; i < n; i++ {
+		dst[i] = src[i] ^ k[i]
+	}
+}
+
+// aesCtrStream returns a cipher.Stream seeded from key, for tests that
+// just need "some" pseudo-random bytes rather than a fixed sequence.
+func aesCtrStream(key [16]byte) cipher.Stream {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err)
+	}
+	var iv [16]byte
+	return cipher.NewCTR(block, iv[:])
+}
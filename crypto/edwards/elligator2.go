@@ -0,0 +1,158 @@
+package edwards
+
+import (
+	"crypto/cipher"
+	"math/big"
+
+	"dissent/crypto"
+)
+
+// el2param implements the "Elligator 2" uniform point encoding
+// (Bernstein, Hamburg, Krasnov, Lange, "Elligator: elliptic-curve points
+// indistinguishable from uniform random strings") for twisted Edwards
+// curves whose birationally-equivalent Montgomery curve
+// v^2 = u^3 + A*u^2 + u has 'A' such that A^2-4 is non-square,
+// e.g. Curve25519/Ed25519. Unlike Elligator 1 this requires no
+// restriction on the Edwards curve's 'a' parameter being a square.
+type el2param struct {
+	c *curve
+
+	eps  crypto.ModInt // fixed non-square field element
+	montA crypto.ModInt // Montgomery A, derived from the Edwards a,d
+	rootNeg2A crypto.ModInt // sqrt(-A-2), used by the birational map
+}
+
+// init sets up el2param for curve c, given the non-square element u
+// from the curve's Param.Elligator2u.
+func (e *el2param) init(c *curve, u *big.Int) *el2param {
+	e.c = c
+	e.eps.Init(u, &c.P)
+
+	// Montgomery A from twisted Edwards (a,d): A = 2*(a+d)/(a-d)
+	var apd, amd, two crypto.ModInt
+	two.Init64(2, &c.P)
+	apd.Add(&c.a, &c.d)
+	amd.Sub(&c.a, &c.d)
+	e.montA.Div(&apd, &amd)
+	e.montA.Mul(&e.montA, &two)
+
+	// rootNeg2A = sqrt(-A-2), needed to map Montgomery (u,v) to
+	// twisted Edwards (x,y) = (rootNeg2A*u/v, (u-1)/(u+1))
+	var negAm2 crypto.ModInt
+	negAm2.Add(&e.montA, &two).Neg(&negAm2)
+	if !e.rootNeg2A.Sqrt(&negAm2) {
+		panic("Elligator 2 curve parameters have no sqrt(-A-2)")
+	}
+
+	return e
+}
+
+// HideLen returns the length of an Elligator 2 representative,
+// one bit short of a full field element so the high bit stays free.
+func (e *el2param) HideLen() int {
+	return (e.c.P.BitLen() - 1 + 7) / 8
+}
+
+// HideEncode maps point p to a uniform representative,
+// or returns nil if p has no such representative
+// (happens for about three quarters of all curve points: about half
+// have no square root r below, and of the rest only the one of
+// (x,y), (-x,y) that HideDecode reconstructs is accepted; callers
+// should retry with a freshly-picked point on failure).
+func (e *el2param) HideEncode(p point, rand cipher.Stream) []byte {
+	c := e.c
+	x, y := p.getXY()
+
+	// Recover the Montgomery u-coordinate from Edwards (x,y):
+	// u = (1+y)/(1-y)
+	var one, ymo, ypo, u crypto.ModInt
+	one.Init64(1, &c.P)
+	ypo.Add(&one, y)
+	ymo.Sub(&one, y)
+	u.Div(&ypo, &ymo)
+
+	// r is a representative iff -eps*r^2 = u/(u+A) has a square root,
+	// and u != -A (the point at which Elligator 2 is undefined).
+	var uPlusA, t, r crypto.ModInt
+	uPlusA.Add(&u, &e.montA)
+	if uPlusA.Equal(&c.zero) {
+		return nil
+	}
+	t.Div(&u, &uPlusA).Neg(&t).Div(&t, &e.eps)
+	if !r.Sqrt(&t) {
+		return nil
+	}
+
+	// HideDecode reconstructs x as rootNeg2A*u/v for whichever v
+	// Sqrt(u^3+A*u^2+u) happens to return; since that's only one of
+	// the two square roots, it recovers p's actual x only about half
+	// the time, with the other half instead landing on (-x,y) -- a
+	// distinct, equally valid curve point. Recompute that same v here
+	// and compare against p's real x so we never hand back an r that
+	// decodes to the wrong point.
+	var v2, uu, v crypto.ModInt
+	v2.Mul(&u, &u)
+	uu.Add(&v2, &e.montA).Mul(&uu, &u).Add(&uu, &u) // u^3 + A*u^2 + u
+	if !v.Sqrt(&uu) {
+		// Shouldn't happen: u is on the curve whenever t above has a
+		// square root, since both come from the same birational map.
+		return nil
+	}
+	var xCanon crypto.ModInt
+	xCanon.Mul(&e.rootNeg2A, &u).Div(&xCanon, &v)
+	if !xCanon.Equal(x) {
+		return nil
+	}
+
+	// Elligator 2 always produces one of two representatives for r;
+	// we have no preference so simply pick the positive root.
+	if c.coordSign(&r) != 0 {
+		r.Neg(&r)
+	}
+
+	b := r.Encode()
+	reverse(b, b)
+	return b
+}
+
+// HideDecode inverse-maps a uniform representative back to a point.
+func (e *el2param) HideDecode(p point, representative []byte) {
+	c := e.c
+
+	b := make([]byte, len(representative))
+	reverse(b, representative)
+
+	var r, rr crypto.ModInt
+	r.M = &c.P
+	r.SetBytes(b)
+	rr.Mul(&r, &r)
+
+	// u = -A/(1+eps*r^2), selecting whichever of u,u' is on-curve
+	var denom, u crypto.ModInt
+	denom.Mul(&e.eps, &rr).Add(&c.one, &denom)
+	u.Neg(&e.montA).Div(&u, &denom)
+
+	var v2, uu, t crypto.ModInt
+	v2.Mul(&u, &u)
+	uu.Add(&v2, &e.montA).Mul(&uu, &u).Add(&uu, &u) // u^3 + A*u^2 + u
+	var v crypto.ModInt
+	if !v.Sqrt(&uu) {
+		// u is not on the curve; the other candidate is.
+		u.Neg(&u).Sub(&u, &e.montA)
+		t.Mul(&u, &u)
+		uu.Add(&t, &e.montA).Mul(&uu, &u).Add(&uu, &u)
+		if !v.Sqrt(&uu) {
+			panic("Elligator 2 representative decodes to no point")
+		}
+	}
+
+	// Birational map from Montgomery (u,v) to Edwards (x,y):
+	// x = rootNeg2A*u/v, y = (u-1)/(u+1)
+	var x, y, ymo, ypo crypto.ModInt
+	x.Mul(&e.rootNeg2A, &u).Div(&x, &v)
+	ymo.Sub(&u, &c.one)
+	ypo.Add(&u, &c.one)
+	y.Div(&ymo, &ypo)
+
+	p.initXY(&x.V, &y.V, c.self)
+}
@@ -0,0 +1,39 @@
+package edwards
+
+import "testing"
+
+// TestElligator2RoundTrip checks that HideDecode(HideEncode(P)) always
+// reproduces P exactly, guarding against the sign-confusion bug where
+// HideEncode derived r from y alone and never checked whether decode
+// would land on (x,y) or its sibling (-x,y).
+func TestElligator2RoundTrip(t *testing.T) {
+	p := ed25519TestParam()
+	group := NewExtendedCurve(p, true)
+	c := group.(rawCurve).curveParam()
+	if c.hide == nil {
+		t.Fatal("curve has no Elligator 2 hiding configured")
+	}
+
+	rand := aesCtrStream([16]byte{1})
+
+	successes := 0
+	for i := 0; i < 500 && successes < 20; i++ {
+		P := group.Point().(point)
+		P.Pick(nil, rand)
+
+		rep := c.hide.HideEncode(P, rand)
+		if rep == nil {
+			continue // about three quarters of all points have no representative; expected
+		}
+		successes++
+
+		Q := group.Point().(point)
+		c.hide.HideDecode(Q, rep)
+		if !Q.Equal(P) {
+			t.Fatalf("HideDecode(HideEncode(P)) != P")
+		}
+	}
+	if successes == 0 {
+		t.Fatal("no point was ever representable; Elligator 2 setup is broken")
+	}
+}
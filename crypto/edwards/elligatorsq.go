@@ -0,0 +1,93 @@
+package edwards
+
+import (
+	"crypto/cipher"
+)
+
+// elsqparam implements Elligator Squared (Tibouchi, "Elligator Squared:
+// Uniform Points on Elliptic Curves of Unrestricted Period Without
+// Finite-Field Inversions"), layered atop an underlying partial
+// injective map f (Elligator 1 or Elligator 2). Where f alone only
+// hides part of a curve's points (about a quarter for this package's
+// el2param) and is restricted to curves
+// meeting f's algebraic preconditions, Elligator Squared represents
+// *every* point on *any* twist-secure curve as a statistically uniform
+// bitstring, at the cost of doubling the representative length: a
+// point P is encoded as r1||r2 such that f(r1)+f(r2) = P.
+type elsqparam struct {
+	c *curve
+	f hiding // underlying map reused to build each half of the encoding
+}
+
+// init sets up elsqparam to layer Elligator Squared atop the
+// underlying hiding f (an *el2param for this curve -- the only
+// Elligator map this package implements).
+func (e *elsqparam) init(c *curve, f hiding) *elsqparam {
+	e.c = c
+	e.f = f
+	return e
+}
+
+// HideLen returns the representative length: twice that of the
+// underlying map, since the encoding concatenates two of its preimages.
+func (e *elsqparam) HideLen() int {
+	return 2 * e.f.HideLen()
+}
+
+// HideEncode maps point p to a uniform representative r1||r2
+// such that f(r1)+f(r2) == p.
+func (e *elsqparam) HideEncode(p point, rand cipher.Stream) []byte {
+	c := e.c
+	hl := e.f.HideLen()
+
+	for {
+		// Rejection-sample a uniformly random r1,
+		// and decode it to get a uniformly random curve point P1.
+		r1 := make([]byte, hl)
+		rand.XORKeyStream(r1, r1)
+		p1 := c.self.Point().(point)
+		if !e.decodeSafely(p1, r1) {
+			continue
+		}
+
+		// We need r2 := f^-1(p - P1).  Since f need not be surjective,
+		// this may not exist; if so, retry with a fresh r1.
+		p2 := c.self.Point()
+		p2.Sub(p, p1)
+
+		r2 := e.f.HideEncode(p2.(point), rand)
+		if r2 == nil {
+			continue
+		}
+
+		return append(append([]byte{}, r1...), r2...)
+	}
+}
+
+// decodeSafely is a best-effort HideDecode: the underlying map may
+// reject some inputs outright by panicking, so we report whether
+// decoding completed rather than panicking. The decoded point is
+// not checked for subgroup membership.
+func (e *elsqparam) decodeSafely(p point, r []byte) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	e.f.HideDecode(p, r)
+	return true
+}
+
+// HideDecode recovers the point encoded by representative = r1||r2,
+// as P = f(r1) + f(r2).
+func (e *elsqparam) HideDecode(p point, representative []byte) {
+	hl := e.f.HideLen()
+	r1, r2 := representative[:hl], representative[hl:]
+
+	p1 := e.c.self.Point().(point)
+	p2 := e.c.self.Point().(point)
+	e.f.HideDecode(p1, r1)
+	e.f.HideDecode(p2, r2)
+
+	p.Add(p1, p2)
+}
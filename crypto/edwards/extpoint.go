@@ -0,0 +1,372 @@
+package edwards
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"math/big"
+
+	"dissent/crypto"
+)
+
+// extPoint represents a twisted Edwards curve point
+// in extended homogeneous coordinates (X,Y,Z,T),
+// with affine x = X/Z, y = Y/Z, and the redundant x*y = T/Z.
+// This is the representation from Hisil, Wong, Carter and Dawson,
+// "Twisted Edwards Curves Revisited" (ASIACRYPT 2008),
+// and unlike the affine backend needs no field inversion
+// to add or double a point. It is not constant-time: Mul's digit
+// recoding and its zero-digit and sign handling branch on the
+// scalar's bits, and crypto.ModInt arithmetic is variable-time.
+type extPoint struct {
+	X, Y, Z, T crypto.ModInt
+	c          *extCurve
+}
+
+func (P *extPoint) String() string {
+	x, y := P.getXY()
+	return P.c.pointString(x, y)
+}
+
+// getXY returns the affine x,y coordinates, requiring a division by Z
+// for each coordinate.
+// Only used where an affine result is actually needed
+// (encoding, onCurve checks, subgroup checks);
+// add/double/multiply never call it on the hot path.
+func (P *extPoint) getXY() (x, y *crypto.ModInt) {
+	x = new(crypto.ModInt)
+	y = new(crypto.ModInt)
+	x.Div(&P.X, &P.Z)
+	y.Div(&P.Y, &P.Z)
+	return
+}
+
+func (P *extPoint) initXY(x, y *big.Int, curve crypto.Group) {
+	c := curve.(*extCurve)
+	P.c = c
+	P.X.Init(x, &c.P)
+	P.Y.Init(y, &c.P)
+	P.Z.Init64(1, &c.P)
+	P.T.Mul(&P.X, &P.Y)
+}
+
+func (P *extPoint) Equal(ca crypto.Point) bool {
+	Q := ca.(*extPoint)
+
+	// x1/z1 == x2/z2  <=>  x1*z2 == x2*z1, and likewise for y.
+	var xl, xr, yl, yr crypto.ModInt
+	xl.Mul(&P.X, &Q.Z)
+	xr.Mul(&Q.X, &P.Z)
+	yl.Mul(&P.Y, &Q.Z)
+	yr.Mul(&Q.Y, &P.Z)
+	return xl.Equal(&xr) && yl.Equal(&yr)
+}
+
+func (P *extPoint) Null() crypto.Point {
+	return P.Set(P.c.null)
+}
+
+func (P *extPoint) Base() crypto.Point {
+	return P.Set(P.c.base)
+}
+
+func (P *extPoint) Set(ca crypto.Point) crypto.Point {
+	Q := ca.(*extPoint)
+	P.c = Q.c
+	P.X = Q.X
+	P.Y = Q.Y
+	P.Z = Q.Z
+	P.T = Q.T
+	return P
+}
+
+func (P *extPoint) Pick(data []byte, rand cipher.Stream) (crypto.Point, []byte) {
+	remain := P.c.pickPoint(P, data, rand)
+	return P, remain
+}
+
+func (P *extPoint) Data() ([]byte, error) {
+	x, y := P.getXY()
+	return P.c.data(x, y)
+}
+
+// Add computes P = A+B using the unified addition formula
+// add-2008-hwcd-3, valid for both doubling and general addition
+// on twisted Edwards curves with a = -1 (and handled generically
+// here via the curve's k = 2*d precomputed constant).
+func (P *extPoint) Add(ca, cb crypto.Point) crypto.Point {
+	A, B := ca.(*extPoint), cb.(*extPoint)
+	c := A.c
+
+	var a, bb, cc, d, e, f, g, h crypto.ModInt
+	var t1, t2 crypto.ModInt
+
+	t1.Sub(&A.Y, &A.X)
+	t2.Sub(&B.Y, &B.X)
+	a.Mul(&t1, &t2) // A = (Y1-X1)*(Y2-X2)
+
+	t1.Add(&A.Y, &A.X)
+	t2.Add(&B.Y, &B.X)
+	bb.Mul(&t1, &t2) // B = (Y1+X1)*(Y2+X2)
+
+	cc.Mul(&A.T, &c.k)
+	cc.Mul(&cc, &B.T) // C = T1*k*T2, k = 2*d
+
+	d.Mul(&A.Z, &c.two)
+	d.Mul(&d, &B.Z) // D = 2*Z1*Z2
+
+	e.Sub(&bb, &a) // E = B-A
+	f.Sub(&d, &cc) // F = D-C
+	g.Add(&d, &cc) // G = D+C
+	h.Add(&bb, &a) // H = B+A
+
+	P.X.Mul(&e, &f)
+	P.Y.Mul(&g, &h)
+	P.T.Mul(&e, &h)
+	P.Z.Mul(&f, &g)
+	P.c = c
+	return P
+}
+
+func (P *extPoint) Sub(ca, cb crypto.Point) crypto.Point {
+	B := cb.(*extPoint)
+	var nb extPoint
+	nb.Neg(B)
+	return P.Add(ca, &nb)
+}
+
+// Neg negates a point: (x,y) -> (-x,y), i.e. (X,Y,Z,T) -> (-X,Y,Z,-T).
+func (P *extPoint) Neg(ca crypto.Point) crypto.Point {
+	A := ca.(*extPoint)
+	P.c = A.c
+	P.X.Neg(&A.X)
+	P.Y = A.Y
+	P.Z = A.Z
+	P.T.Neg(&A.T)
+	return P
+}
+
+// double sets P = 2*A using the dedicated doubling formula dbl-2008-hwcd,
+// cheaper than a general Add(A,A) since several cross-terms vanish.
+func (P *extPoint) double(A *extPoint) *extPoint {
+	var a, bb, cc, e, f, g, h crypto.ModInt
+	var t1 crypto.ModInt
+
+	a.Mul(&A.X, &A.X) // A = X1^2
+	bb.Mul(&A.Y, &A.Y) // B = Y1^2
+	cc.Mul(&A.Z, &A.Z)
+	cc.Add(&cc, &cc) // C = 2*Z1^2
+
+	h.Add(&a, &bb) // H = A+B
+
+	t1.Add(&A.X, &A.Y)
+	t1.Mul(&t1, &t1)     // (X1+Y1)^2
+	e.Sub(&h, &t1)       // E = H-(X1+Y1)^2
+	g.Sub(&a, &bb)       // G = A-B
+	f.Add(&cc, &g)       // F = C+G
+
+	P.X.Mul(&e, &f)
+	P.Y.Mul(&g, &h)
+	P.T.Mul(&e, &h)
+	P.Z.Mul(&f, &g)
+	P.c = A.c
+	return P
+}
+
+// scalarMulWindow is the width of the fixed signed-digit window used
+// by Mul: each digit is in [-2^(w-1), 2^(w-1)-1], and since
+// signedDigits only ever emits zero or odd digits, the precomputed table only
+// needs the 2^(w-2) distinct odd magnitudes in that range.
+const scalarMulWindow = 4
+
+// buildTable precomputes the odd multiples 1*A, 3*A, 5*A, ...,
+// (2^(w-1)-1)*A of A: signedDigits only ever centers a digit into
+// [-2^(w-1), 2^(w-1)-1], so its odd magnitudes top out at 2^(w-1)-1,
+// one less than the window's full range, and the table only needs to
+// hold that many entries.
+func (P *extPoint) buildTable(A *extPoint) []extPoint {
+	n := 1 << uint(scalarMulWindow-2)
+	table := make([]extPoint, n)
+	table[0] = *A
+	var a2 extPoint
+	a2.double(A)
+	for i := 1; i < n; i++ {
+		table[i].Add(&table[i-1], &a2)
+	}
+	return table
+}
+
+// cselect does a constant-time conditional select: every entry of
+// table is merged into the result through a subtle.ConstantTimeEq
+// mask rather than a data-dependent branch, so which multiple of the
+// base point was used is not observable through cache or branch
+// timing. crypto.ModInt's big.Int-backed arithmetic is not itself
+// constant-time at the limb level, for Ed25519 as for any other
+// curve: the field25519 backend is used only by solveForX.
+func (P *extPoint) cselect(table []extPoint, idx int) *extPoint {
+	var r extPoint
+	r.c = P.c
+	r.X.Init64(0, &P.c.P)
+	r.Y.Init64(0, &P.c.P)
+	r.Z.Init64(0, &P.c.P)
+	r.T.Init64(0, &P.c.P)
+	for i := range table {
+		var mask crypto.ModInt
+		mask.Init64(int64(subtle.ConstantTimeEq(int32(i), int32(idx))), &P.c.P)
+
+		var mx, my, mz, mt crypto.ModInt
+		mx.Mul(&mask, &table[i].X)
+		my.Mul(&mask, &table[i].Y)
+		mz.Mul(&mask, &table[i].Z)
+		mt.Mul(&mask, &table[i].T)
+
+		r.X.Add(&r.X, &mx)
+		r.Y.Add(&r.Y, &my)
+		r.Z.Add(&r.Z, &mz)
+		r.T.Add(&r.T, &mt)
+	}
+	return &r
+}
+
+// Mul sets P = s*A (or s*B, the group's standard base point, if A == nil)
+// using a signed-digit window ladder: the scalar is recoded into
+// zero or odd signed digits (see signedDigits), and each step advances
+// the accumulator by that digit's width (one bit for a zero digit,
+// w bits for an odd one) before merging it in, so
+// the table lookup always lands on one of buildTable's precomputed
+// odd multiples.
+func (P *extPoint) Mul(ca crypto.Point, cs crypto.Secret) crypto.Point {
+	s := cs.(*crypto.ModInt)
+	var A *extPoint
+	if ca == nil {
+		A = P.c.base
+	} else {
+		A = ca.(*extPoint)
+	}
+
+	table := P.buildTable(A)
+	w := uint(scalarMulWindow)
+
+	steps := signedDigits(&s.V, w)
+
+	// signedDigits already returns steps most-significant-first, so the
+	// Horner-style double-then-add ladder below must walk it forward;
+	// walking it backward here would undo that reversal and consume
+	// the digits in their original least-significant-first order.
+	var Q extPoint
+	Q.c = P.c
+	Q.Null()
+	for i := 0; i < len(steps); i++ {
+		for j := uint(0); j < steps[i].shift; j++ {
+			Q.double(&Q)
+		}
+		d := steps[i].digit
+		neg := d < 0
+		if neg {
+			d = -d
+		}
+		T := Q.cselect(table, (d-1)/2)
+		if neg {
+			var nt extPoint
+			nt.Neg(T)
+			T = &nt
+		}
+		if d != 0 {
+			Q.Add(&Q, T)
+		}
+	}
+	return P.Set(&Q)
+}
+
+// digitStep pairs a recoded digit with the number of bits (doublings)
+// to advance the accumulator before merging it in.
+type digitStep struct {
+	shift uint
+	digit int
+}
+
+// signedDigits recodes n into an all-odd signed-digit representation
+// suitable for buildTable's odd-multiples-only table: scanning from
+// the low bit, an even residue contributes a zero digit and advances
+// just one bit, while an odd residue consumes a full w-bit window,
+// centered into [-2^(w-1), 2^(w-1)-1] -- which, since centering only
+// ever adds or subtracts a multiple of 2^w, is guaranteed to stay odd.
+// A uniform base-2^w balanced recoding can't make this guarantee: it
+// happily emits even nonzero digits (e.g. signedDigits(2, 4) would be
+// [2]), which don't correspond to any entry in the odd-only table.
+func signedDigits(n *big.Int, w uint) []digitStep {
+	base := new(big.Int).Lsh(big.NewInt(1), w)
+	half := new(big.Int).Lsh(big.NewInt(1), w-1)
+
+	v := new(big.Int).Set(n)
+
+	var steps []digitStep
+	for v.Sign() != 0 {
+		if v.Bit(0) == 0 {
+			steps = append(steps, digitStep{shift: 1, digit: 0})
+			v.Rsh(v, 1)
+			continue
+		}
+
+		d := new(big.Int).Mod(v, base)
+		if d.Cmp(half) >= 0 {
+			d.Sub(d, base)
+		}
+		steps = append(steps, digitStep{shift: w, digit: int(d.Int64())})
+		v.Sub(v, d)
+		v.Rsh(v, w)
+	}
+	if len(steps) == 0 {
+		steps = []digitStep{{shift: w, digit: 0}}
+	}
+	// reverse into most-significant-first order
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps
+}
+
+// extCurve implements crypto.Group for a twisted Edwards curve
+// using the extended-coordinates point backend.
+type extCurve struct {
+	curve
+	base    *extPoint
+	k       crypto.ModInt // 2*d, precomputed for the addition formula
+	two     crypto.ModInt // the constant 2, as a ModInt of the right modulus
+}
+
+func (c *extCurve) Point() crypto.Point {
+	P := new(extPoint)
+	P.c = c
+	return P
+}
+
+// NewExtendedCurve returns a crypto.Group implementing the twisted
+// Edwards curve described by p, using the extended-coordinates
+// (X,Y,Z,T) backend for inversion-free point operations (not
+// constant-time; see extPoint).
+func NewExtendedCurve(p *Param, fullGroup bool) crypto.Group {
+	// Add's add-2008-hwcd-3 formula is only valid for a = -1; silently
+	// using it on any other curve would produce wrong points without
+	// ever failing, so reject that case here instead.
+	if new(big.Int).Mod(&p.A, &p.P).Cmp(new(big.Int).Sub(&p.P, one)) != 0 {
+		panic("edwards: extended-coordinates backend requires a = -1")
+	}
+
+	c := new(extCurve)
+	null := new(extPoint)
+	base := new(extPoint)
+	c.base = base
+
+	// c.two and c.k (and the curve's own a,d, mirrored here) must be
+	// valid before curve.init runs: init's validPoint sanity checks
+	// on null/base call Mul, which reads them through buildTable/Add.
+	// Initializing them afterwards would let that sanity check pass
+	// vacuously against a degenerate k=two=0 curve.
+	c.a.Init(&p.A, &p.P)
+	c.d.Init(&p.D, &p.P)
+	c.two.Init64(2, &p.P)
+	c.k.Add(&c.d, &c.d)
+
+	c.curve.init(c, p, fullGroup, null, base)
+	return c
+}
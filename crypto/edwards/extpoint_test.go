@@ -0,0 +1,47 @@
+package edwards
+
+import (
+	"testing"
+
+	"dissent/crypto"
+)
+
+// TestNewExtendedCurve checks that constructing a real curve succeeds
+// and its base point has the expected order, guarding against the nil
+// *extCurve dereference that buildTable's unset table[i].c used to
+// trigger on every call (NewExtendedCurve panicked unconditionally).
+func TestNewExtendedCurve(t *testing.T) {
+	p := ed25519TestParam()
+	group := NewExtendedCurve(p, false)
+
+	B := group.Point().Base()
+	null := group.Point().Null()
+
+	var order = group.(rawCurve).curveParam().order
+	Q := group.Point().Mul(B, &order)
+	if !Q.Equal(null) {
+		t.Fatal("base point does not have the expected order")
+	}
+}
+
+// TestExtPointScalarMul checks that s*B, computed via Mul's
+// fixed-window ladder (which exercises buildTable/Add directly),
+// agrees with repeated addition for a handful of small scalars.
+func TestExtPointScalarMul(t *testing.T) {
+	p := ed25519TestParam()
+	group := NewExtendedCurve(p, false)
+
+	order := &group.(rawCurve).curveParam().order
+	B := group.Point().Base()
+	acc := group.Point().Null()
+	for i := int64(1); i <= 20; i++ {
+		acc.Add(acc, B)
+
+		var s crypto.ModInt
+		s.Init64(i, &order.V)
+		got := group.Point().Mul(B, &s)
+		if !got.Equal(acc) {
+			t.Fatalf("%d*B via Mul != %d*B via repeated addition", i, i)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package edwards
+
+import (
+	"math/big"
+
+	"dissent/crypto"
+	"dissent/crypto/field25519"
+)
+
+// ed25519P is 2^255-19, the prime field25519 implements. Curves whose
+// field doesn't match this (e.g. Ed448-Goldilocks, or any curve over
+// a smaller/larger prime) always fall back to the generic, slower but
+// arbitrary-modulus crypto.ModInt path in solveForX.
+var ed25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// hasFastSqrt reports whether curve c can use the constant-time
+// field25519 backend's combined inverse-square-root formula instead
+// of the generic, math/big-based solveForX.
+func (c *curve) hasFastSqrt() bool {
+	return c.P.Cmp(ed25519P) == 0
+}
+
+// fieldElement converts a ModInt (reduced mod ed25519P) into a
+// field25519.Element.
+func fieldElement(m *crypto.ModInt) field25519.Element {
+	var b [32]byte
+	enc := m.Encode() // big-endian, sized to the modulus
+	copy(b[32-len(enc):], enc)
+	reverse(b[:], b[:]) // field25519 wants little-endian limbs
+
+	var e field25519.Element
+	field25519.FeFromBytes(&e, &b)
+	return e
+}
+
+// modIntFromField converts a field25519.Element back into a ModInt
+// reduced mod ed25519P.
+func modIntFromField(e *field25519.Element) crypto.ModInt {
+	var b [32]byte
+	field25519.FeToBytes(&b, e)
+	reverse(b[:], b[:]) // back to big-endian for ModInt.SetBytes
+
+	var m crypto.ModInt
+	m.M = ed25519P
+	m.SetBytes(b[:])
+	return m
+}
+
+func feEqual(a, b *field25519.Element) bool {
+	var ba, bb [32]byte
+	field25519.FeToBytes(&ba, a)
+	field25519.FeToBytes(&bb, b)
+	return ba == bb
+}
+
+// fastSolveForX is solveForX's Ed25519 fast path. It assumes a == -1,
+// true of every curve defined over ed25519P in this package, folding
+// that assumption directly into u,v rather than computing the
+// general a-d*y^2 denominator. It then computes the candidate square
+// root of u/v = (1-y^2)/(a-d*y^2) via the combined inverse-square-root
+// formula
+//
+//	beta = (u*v^3) * (u*v^7)^((p-5)/8)
+//
+// (field25519.FePow22523), needing one fixed-length exponentiation
+// instead of a general modular inversion followed by a general
+// square root. The field25519 arithmetic is constant-time; the
+// conversions to and from crypto.ModInt and the choice between the
+// two candidate roots below are not.
+func (c *curve) fastSolveForX(x, y *crypto.ModInt) bool {
+	if c.fastD == nil {
+		d := fieldElement(&c.d)
+		c.fastD = &d
+	}
+	yf := fieldElement(y)
+
+	var one, ysq, u, dysq, v field25519.Element
+	one[0] = 1
+	field25519.FeSquare(&ysq, &yf)
+	field25519.FeSub(&u, &ysq, &one)  // u = y^2-1
+	field25519.FeMul(&dysq, c.fastD, &ysq)
+	field25519.FeAdd(&v, &dysq, &one) // v = d*y^2+1
+
+	var v2, v3, v6, v7, uv3, uv7, t, xc field25519.Element
+	field25519.FeSquare(&v2, &v)
+	field25519.FeMul(&v3, &v2, &v)
+	field25519.FeSquare(&v6, &v3)
+	field25519.FeMul(&v7, &v6, &v)
+	field25519.FeMul(&uv3, &u, &v3)
+	field25519.FeMul(&uv7, &u, &v7)
+	field25519.FePow22523(&t, &uv7)
+	field25519.FeMul(&xc, &uv3, &t)
+
+	var vxx, negu field25519.Element
+	field25519.FeSquare(&vxx, &xc)
+	field25519.FeMul(&vxx, &vxx, &v)
+	if feEqual(&vxx, &u) {
+		*x = modIntFromField(&xc)
+		return true
+	}
+	field25519.FeNeg(&negu, &u)
+	if feEqual(&vxx, &negu) {
+		// x *= sqrt(-1): Ed25519's fixed non-residue, baked in as a
+		// constant rather than computed, since it never changes.
+		var sqrtM1 field25519.Element
+		field25519.FeFromBytes(&sqrtM1, &ed25519SqrtM1)
+		field25519.FeMul(&xc, &xc, &sqrtM1)
+		*x = modIntFromField(&xc)
+		return true
+	}
+	return false
+}
+
+// ed25519SqrtM1 is the little-endian encoding of a fixed square root
+// of -1 mod 2^255-19 (2^((p-1)/4) mod p), used by fastSolveForX's
+// second candidate branch exactly as RFC 8032's decompression does.
+var ed25519SqrtM1 = [32]byte{
+	0xb0, 0xa0, 0x0e, 0x4a, 0x27, 0x1b, 0xee, 0xc4,
+	0x78, 0xe4, 0x2f, 0xad, 0x06, 0x18, 0x43, 0x2f,
+	0xa7, 0xd7, 0xfb, 0x3d, 0x99, 0x00, 0x4d, 0x2b,
+	0x0b, 0xdf, 0xc1, 0x4f, 0x80, 0x24, 0x83, 0x2b,
+}
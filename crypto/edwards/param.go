@@ -0,0 +1,33 @@
+package edwards
+
+import (
+	"math/big"
+)
+
+// Param holds the parameters of a twisted Edwards curve,
+// satisfying the equation a*x^2 + y^2 = 1 + d*x^2*y^2 mod P.
+type Param struct {
+	Name string // Name of curve
+
+	P big.Int // Prime defining the underlying field
+	Q big.Int // Order of the prime-order subgroup
+	R int     // Cofactor of the full curve group relative to Q
+
+	A, D big.Int // Curve equation parameters a,d
+
+	FBX, FBY big.Int // Base point for the full group, or 0,0 if none given
+	PBX, PBY big.Int // Base point for the prime-order subgroup
+
+	// Elligator2u is the non-square field element u used by the
+	// Elligator 2 map, or 0 if Elligator 2 does not apply to this curve
+	// (i.e., the curve's Montgomery form does not have 'a' square
+	// and 'd' non-square).
+	Elligator2u big.Int
+
+	// ElligatorSqM indicates that points should be hidden using
+	// Elligator Squared layered atop Elligator 2, doubling the
+	// representative length. It takes effect only when Elligator2u is
+	// nonzero: Elligator 1 is not implemented, so a curve without
+	// Elligator 2 gets no hiding at all.
+	ElligatorSqM bool
+}
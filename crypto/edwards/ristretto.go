@@ -0,0 +1,321 @@
+package edwards
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"dissent/crypto"
+)
+
+// RistrettoPoint wraps a point on a cofactor-8 twisted Edwards curve
+// (e.g. Ed25519) to give it Ristretto255 prime-order group semantics:
+// Equal compares the whole coset {P, P+T, P+2T, P+3T} of the 4-torsion
+// point T rather than raw coordinates, so none of decodePoint's
+// cofactor caveats apply to callers working only through this type.
+type RistrettoPoint struct {
+	g *RistrettoGroup
+	p point
+}
+
+func (P *RistrettoPoint) String() string {
+	return P.p.String()
+}
+
+// Equal implements the cofactor-agnostic equality test
+// x1*y2 == x2*y1 || x1*x2 == y1*y2, true for P == Q iff P and Q
+// denote the same element of the quotient group E / E[4], regardless
+// of which torsion coset member either point's coordinates happen to
+// be: the 4-torsion point T=(i,0) (i = sqrt(-1)) maps (x,y) to
+// (i*y,i*x), which the first check alone (valid only for the
+// 2-torsion shift by (-1,-1)) fails to recognize as equal.
+func (P *RistrettoPoint) Equal(cb crypto.Point) bool {
+	Q := cb.(*RistrettoPoint)
+	x1, y1 := P.p.getXY()
+	x2, y2 := Q.p.getXY()
+	var xy1, xy2, xx, yy crypto.ModInt
+	xy1.Mul(x1, y2)
+	xy2.Mul(x2, y1)
+	xx.Mul(x1, x2)
+	yy.Mul(y1, y2)
+	return xy1.Equal(&xy2) || xx.Equal(&yy)
+}
+
+func (P *RistrettoPoint) Null() crypto.Point {
+	P.p.Null()
+	return P
+}
+
+func (P *RistrettoPoint) Base() crypto.Point {
+	P.p.Base()
+	return P
+}
+
+func (P *RistrettoPoint) Pick(data []byte, rand cipher.Stream) (crypto.Point, []byte) {
+	_, remain := P.p.Pick(data, rand)
+	return P, remain
+}
+
+func (P *RistrettoPoint) Data() ([]byte, error) {
+	return P.p.Data()
+}
+
+func (P *RistrettoPoint) Add(ca, cb crypto.Point) crypto.Point {
+	A, B := ca.(*RistrettoPoint), cb.(*RistrettoPoint)
+	P.p.Add(A.p, B.p)
+	return P
+}
+
+func (P *RistrettoPoint) Sub(ca, cb crypto.Point) crypto.Point {
+	A, B := ca.(*RistrettoPoint), cb.(*RistrettoPoint)
+	P.p.Sub(A.p, B.p)
+	return P
+}
+
+func (P *RistrettoPoint) Neg(ca crypto.Point) crypto.Point {
+	A := ca.(*RistrettoPoint)
+	P.p.Neg(A.p)
+	return P
+}
+
+func (P *RistrettoPoint) Mul(ca crypto.Point, s crypto.Secret) crypto.Point {
+	if ca == nil {
+		P.p.Mul(nil, s)
+		return P
+	}
+	A := ca.(*RistrettoPoint)
+	P.p.Mul(A.p, s)
+	return P
+}
+
+// RistrettoGroup implements crypto.Group, handing out RistrettoPoints
+// backed by c's own point representation (affine or extended) while
+// using the Ristretto255 wire format instead of c.encodePoint.
+type RistrettoGroup struct {
+	c  *curve
+	e2 *el2param // lazily built one-way map, used only by HashToGroup
+
+	sqrtM1         crypto.ModInt // sqrt(-1) mod c.P, used by sqrtRatioM1
+	invSqrtAMinusD crypto.ModInt // invsqrt(a-d), Encode's "enchanted denominator" factor
+}
+
+// NewRistrettoGroup returns the Ristretto255-style prime-order group
+// wrapping group, which must be an edwards curve (any backend) with
+// a = -1 and cofactor 8, e.g. Ed25519 -- the only curve shape the
+// Ristretto255 encode/decode formulas below are valid for.
+func NewRistrettoGroup(group crypto.Group) *RistrettoGroup {
+	rc, ok := group.(rawCurve)
+	if !ok {
+		panic("ristretto: not an edwards curve group")
+	}
+	c := rc.curveParam()
+	if c.R != 8 {
+		panic("ristretto: underlying curve's cofactor must be 8")
+	}
+	var negOne crypto.ModInt
+	negOne.Sub(&c.zero, &c.one)
+	if !c.a.Equal(&negOne) {
+		panic("ristretto: underlying curve must have a = -1")
+	}
+
+	g := &RistrettoGroup{c: c}
+	if !g.sqrtM1.Sqrt(&negOne) {
+		panic("ristretto: curve field has no sqrt(-1)")
+	}
+	var aMinusD crypto.ModInt
+	aMinusD.Sub(&c.a, &c.d)
+	isSquare, invSqrtAMinusD := g.sqrtRatioM1(&c.one, &aMinusD)
+	if !isSquare {
+		panic("ristretto: curve parameters have no invsqrt(a-d)")
+	}
+	g.invSqrtAMinusD = invSqrtAMinusD
+
+	return g
+}
+
+// sqrtRatioM1 computes a square root related to u/v, implementing the
+// ristretto255 SQRT_RATIO_M1 primitive: if u/v is a square, it returns
+// (true, sqrt(u/v)); otherwise it returns (false, sqrt(i*u/v)), which
+// exists because exactly one of u/v and i*u/v is square whenever v is
+// nonzero (i = sqrtM1 = sqrt(-1), and the field has p = 5 mod 8).
+func (g *RistrettoGroup) sqrtRatioM1(u, v *crypto.ModInt) (bool, crypto.ModInt) {
+	var uv, r crypto.ModInt
+	uv.Div(u, v)
+	if r.Sqrt(&uv) {
+		return true, r
+	}
+	var iuv crypto.ModInt
+	iuv.Mul(&g.sqrtM1, &uv)
+	r.Sqrt(&iuv)
+	return false, r
+}
+
+func (g *RistrettoGroup) String() string {
+	return "Ristretto(" + g.c.Name + ")"
+}
+
+func (g *RistrettoGroup) PrimeOrder() bool { return true }
+
+func (g *RistrettoGroup) SecretLen() int { return g.c.SecretLen() }
+
+func (g *RistrettoGroup) Secret() crypto.Secret { return g.c.Secret() }
+
+// PointLen returns the length of a Ristretto255-encoded point:
+// one field element, unlike the edwards curve's own encoding which
+// sometimes needs an extra byte to carry the x sign bit.
+func (g *RistrettoGroup) PointLen() int {
+	return (g.c.P.BitLen() + 7) / 8
+}
+
+func (g *RistrettoGroup) Point() crypto.Point {
+	return &RistrettoPoint{g: g, p: g.c.self.Point().(point)}
+}
+
+// Encode returns the canonical Ristretto255 encoding of P: the unique
+// representative of its coset {P, P+T, P+2T, P+3T}, following the
+// reference "Decaf"/Ristretto255 compress algorithm (extended
+// coordinates x,y,z=1,t=x*y), so that every member of the coset
+// encodes identically.
+func (g *RistrettoGroup) Encode(P *RistrettoPoint) []byte {
+	c := g.c
+	x, y := P.p.getXY()
+
+	var ypo, ymo, u1 crypto.ModInt
+	ypo.Add(&c.one, y)
+	ymo.Sub(&c.one, y)
+	u1.Mul(&ypo, &ymo) // u1 = (z+y)*(z-y), z=1
+
+	var u2 crypto.ModInt
+	u2.Mul(x, y) // u2 = x*y (= t, since z=1)
+
+	var u2sq, u1u2sq crypto.ModInt
+	u2sq.Mul(&u2, &u2)
+	u1u2sq.Mul(&u1, &u2sq)
+	_, invsqrt := g.sqrtRatioM1(&c.one, &u1u2sq) // known square for a valid point
+
+	var den1, den2 crypto.ModInt
+	den1.Mul(&invsqrt, &u1)
+	den2.Mul(&invsqrt, &u2)
+
+	var zInv crypto.ModInt
+	zInv.Mul(&den1, &den2).Mul(&zInv, &u2) // z_inv = den1*den2*t
+
+	var enchanted crypto.ModInt
+	enchanted.Mul(&den1, &g.invSqrtAMinusD)
+
+	var tZinv crypto.ModInt
+	tZinv.Mul(&u2, &zInv)
+
+	ox, oy, denInv := x, y, &den2
+	if c.coordSign(&tZinv) != 0 {
+		// Rotate to the coset member the spec's sign convention picks.
+		var ix0, iy0 crypto.ModInt
+		ix0.Mul(x, &g.sqrtM1)
+		iy0.Mul(y, &g.sqrtM1)
+		ox, oy = &iy0, &ix0
+		denInv = &enchanted
+	}
+
+	var xZinv crypto.ModInt
+	xZinv.Mul(ox, &zInv)
+	finalY := *oy
+	if c.coordSign(&xZinv) != 0 {
+		finalY.Neg(&finalY)
+	}
+
+	var s crypto.ModInt
+	s.Sub(&c.one, &finalY).Mul(&s, denInv)
+	if c.coordSign(&s) != 0 {
+		s.Neg(&s)
+	}
+
+	b := s.Encode()
+	return reverse(b, b)
+}
+
+// Decode parses a canonical Ristretto255 encoding into P, failing if
+// b does not represent a valid canonical point, following the
+// reference Ristretto255 decompress algorithm.
+func (g *RistrettoGroup) Decode(P *RistrettoPoint, b []byte) error {
+	c := g.c
+	if len(b) != g.PointLen() {
+		return errors.New("ristretto: invalid encoding length")
+	}
+	sb := reverse(make([]byte, len(b)), b)
+
+	var s crypto.ModInt
+	s.M = &c.P
+	s.SetBytes(sb)
+	if s.V.Cmp(&c.P) >= 0 || c.coordSign(&s) != 0 {
+		return errors.New("ristretto: non-canonical or negative encoding")
+	}
+
+	var ss, u1, u2, u2sq crypto.ModInt
+	ss.Mul(&s, &s)
+	u1.Sub(&c.one, &ss) // u1 = 1-s^2
+	u2.Add(&c.one, &ss) // u2 = 1+s^2
+	u2sq.Mul(&u2, &u2)
+
+	var u1sq, du1sq, v crypto.ModInt
+	u1sq.Mul(&u1, &u1)
+	du1sq.Mul(&c.d, &u1sq)
+	v.Add(&du1sq, &u2sq).Neg(&v) // v = -(d*u1^2) - u2^2
+
+	var vu2sq crypto.ModInt
+	vu2sq.Mul(&v, &u2sq)
+	wasSquare, invsqrt := g.sqrtRatioM1(&c.one, &vu2sq)
+	if !wasSquare {
+		return errors.New("ristretto: invalid point encoding")
+	}
+
+	var denX, denY crypto.ModInt
+	denX.Mul(&invsqrt, &u2)
+	denY.Mul(&invsqrt, &denX).Mul(&denY, &v)
+
+	var x, y, two crypto.ModInt
+	two.Init64(2, &c.P)
+	x.Mul(&s, &denX).Mul(&x, &two)
+	if c.coordSign(&x) != 0 {
+		x.Neg(&x)
+	}
+	y.Mul(&u1, &denY)
+
+	var t crypto.ModInt
+	t.Mul(&x, &y)
+	if c.coordSign(&t) != 0 || y.Equal(&c.zero) {
+		return errors.New("ristretto: invalid point encoding")
+	}
+
+	if P.p == nil {
+		P.p = c.self.Point().(point)
+	}
+	P.p.initXY(&x.V, &y.V, c.self)
+	return nil
+}
+
+// oneWayMap is the Elligator 2 map used, unmodified, as the
+// non-invertible one-way function HashToGroup needs: unlike
+// HideDecode's use in el2param it is never required to be paired
+// with an inverse HideEncode.
+func (g *RistrettoGroup) oneWayMap(r []byte) point {
+	if g.e2 == nil {
+		g.e2 = new(el2param).init(g.c, &g.c.Elligator2u)
+	}
+	P := g.c.self.Point().(point)
+	g.e2.HideDecode(P, r)
+	return P
+}
+
+// HashToGroup maps a 64-byte uniform string (e.g. the output of a
+// wide hash function) onto a uniformly distributed group element,
+// by splitting it into two halves, mapping each through Elligator 2,
+// and summing the results -- the standard Ristretto255 hash-to-group
+// construction.
+func (g *RistrettoGroup) HashToGroup(uniform []byte) *RistrettoPoint {
+	half := len(uniform) / 2
+	p1 := g.oneWayMap(uniform[:half])
+	p2 := g.oneWayMap(uniform[half:])
+
+	P := g.Point().(*RistrettoPoint)
+	P.p.Add(p1, p2)
+	return P
+}
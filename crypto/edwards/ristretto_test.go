@@ -0,0 +1,60 @@
+package edwards
+
+import "testing"
+
+// TestRistrettoEncodeDecode checks that Decode(Encode(P)) reproduces a
+// point equal to P under the cofactor-agnostic equality RistrettoPoint
+// defines, across several small multiples of the base point.
+func TestRistrettoEncodeDecode(t *testing.T) {
+	p := ed25519TestParam()
+	group := NewRistrettoGroup(NewExtendedCurve(p, false))
+
+	B := group.Point().(*RistrettoPoint)
+	B.Base()
+
+	acc := group.Point().(*RistrettoPoint)
+	acc.Null()
+	for i := 1; i <= 10; i++ {
+		acc.Add(acc, B)
+
+		enc := group.Encode(acc)
+		if len(enc) != group.PointLen() {
+			t.Fatalf("encoding has length %d, want %d", len(enc), group.PointLen())
+		}
+
+		dec := group.Point().(*RistrettoPoint)
+		if err := group.Decode(dec, enc); err != nil {
+			t.Fatalf("Decode failed: %v", err)
+		}
+		if !dec.Equal(acc) {
+			t.Fatalf("Decode(Encode(%d*B)) != %d*B", i, i)
+		}
+	}
+}
+
+// TestRistrettoCosetInvariant checks the defining property of
+// Ristretto encoding: P, P+T, P+2T, P+3T, the four points of the
+// curve's order-4 torsion coset, all produce the same encoding.
+func TestRistrettoCosetInvariant(t *testing.T) {
+	p := ed25519TestParam()
+	group := NewRistrettoGroup(NewExtendedCurve(p, false))
+	c := group.c
+
+	// T = (sqrt(-1), 0) generates the curve's order-4 torsion subgroup.
+	T := c.self.Point().(point)
+	T.initXY(&group.sqrtM1.V, &c.zero.V, c.self)
+	Tp := &RistrettoPoint{g: group, p: T}
+
+	B := group.Point().(*RistrettoPoint)
+	B.Base()
+	want := group.Encode(B)
+
+	acc := group.Point().(*RistrettoPoint)
+	acc.Base()
+	for i := 1; i <= 3; i++ {
+		acc.Add(acc, Tp)
+		if got := group.Encode(acc); string(got) != string(want) {
+			t.Fatalf("Encode(B+%d*T) = %x, want %x", i, got, want)
+		}
+	}
+}
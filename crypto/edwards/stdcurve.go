@@ -0,0 +1,258 @@
+package edwards
+
+import (
+	"crypto/elliptic"
+	"math/big"
+
+	"dissent/crypto"
+)
+
+// rawCurve exposes the unexported *curve embedded in any concrete
+// edwards.crypto.Group implementation (point.go's affine backend,
+// extpoint.go's extended-coordinates backend, ...), via ordinary
+// Go method promotion - no knowledge of the concrete type is needed.
+type rawCurve interface {
+	curveParam() *curve
+}
+
+func (c *curve) curveParam() *curve {
+	return c
+}
+
+// stdCurve adapts a twisted Edwards curve to the stdlib's
+// crypto/elliptic.Curve interface, for interoperating with code that
+// dispatches on it (TLS certificate parsing, JWK, PKCS#8, crypto/ecdsa).
+// Only the generator is converted from the twisted Edwards model (via
+// Montgomery form), when the adapter is built; Add, Double and
+// ScalarMult work on short Weierstrass coordinates directly and do
+// not use the curve's own point type or encodePoint/decodePoint.
+type stdCurve struct {
+	c *curve
+
+	params *elliptic.CurveParams
+
+	// Montgomery and short-Weierstrass coefficients derived once
+	// from the twisted Edwards a,d, since they depend only on the
+	// curve, not on any particular point.
+	montA, montB   big.Int
+	weierA, weierB big.Int
+}
+
+// AsStdCurve wraps an edwards curve group so it satisfies
+// crypto/elliptic.Curve, converting affine twisted-Edwards (x,y)
+// through the birational map to Montgomery form and then the
+// rational map from Montgomery to short Weierstrass
+// (y^2 = x^3 + a*x + b) - for Ed25519 this is the standard
+// Curve25519 -> Weierstrass25519 correspondence.
+func AsStdCurve(group crypto.Group) elliptic.Curve {
+	rc, ok := group.(rawCurve)
+	if !ok {
+		panic("AsStdCurve: not an edwards curve group")
+	}
+	c := rc.curveParam()
+
+	s := &stdCurve{c: c}
+	s.deriveCoefficients()
+
+	s.params = &elliptic.CurveParams{
+		Name:    c.Name,
+		P:       new(big.Int).Set(&c.P),
+		N:       new(big.Int).Set(&c.order.V),
+		B:       new(big.Int).Set(&s.weierB),
+		BitSize: c.P.BitLen(),
+	}
+
+	// Cache the converted generator.
+	bx, by := c.self.Point().Base().(point).getXY()
+	s.params.Gx, s.params.Gy = s.toWeierstrass(&bx.V, &by.V)
+
+	return s
+}
+
+// deriveCoefficients computes the Montgomery (A,B) and short
+// Weierstrass (a,b) coefficients for the curve's (a,d),
+// following the standard birational correspondences:
+//
+//	A = 2*(a+d)/(a-d), B = 4/(a-d)
+//	a_w = (3-A^2)/(3*B^2), b_w = (2*A^3-9*A)/(27*B^3)
+func (s *stdCurve) deriveCoefficients() {
+	c := s.c
+	var apd, amd, two, three, four crypto.ModInt
+	two.Init64(2, &c.P)
+	three.Init64(3, &c.P)
+	four.Init64(4, &c.P)
+	apd.Add(&c.a, &c.d)
+	amd.Sub(&c.a, &c.d)
+
+	var montA, montB crypto.ModInt
+	montA.Div(&apd, &amd).Mul(&montA, &two)
+	montB.Div(&four, &amd)
+	s.montA.Set(&montA.V)
+	s.montB.Set(&montB.V)
+
+	var a2, a3, b2, b3, num, den, wa, wb crypto.ModInt
+	a2.Mul(&montA, &montA)
+	a3.Mul(&a2, &montA)
+	b2.Mul(&montB, &montB)
+	b3.Mul(&b2, &montB)
+
+	// a_w = (3-A^2)/(3*B^2)
+	num.Sub(&three, &a2)
+	den.Mul(&b2, &three)
+	wa.Div(&num, &den)
+	s.weierA.Set(&wa.V)
+
+	// b_w = (2*A^3-9*A)/(27*B^3)
+	var nine, twentySeven, twoA3, nineA crypto.ModInt
+	nine.Init64(9, &c.P)
+	twentySeven.Init64(27, &c.P)
+	twoA3.Mul(&a3, &two)
+	nineA.Mul(&montA, &nine)
+	num.Sub(&twoA3, &nineA)
+	den.Mul(&b3, &twentySeven)
+	wb.Div(&num, &den)
+	s.weierB.Set(&wb.V)
+}
+
+// toMontgomery converts an affine twisted Edwards point to its
+// Montgomery correspondent: u = (1+y)/(1-y), v = u/x (undefined at
+// x == 0, i.e. only at the curve's identity and 2-torsion points).
+func (s *stdCurve) toMontgomery(x, y *big.Int) (u, v *big.Int) {
+	c := s.c
+	var xx, yy, one, ymo, ypo, um, vm crypto.ModInt
+	xx.Init(x, &c.P)
+	yy.Init(y, &c.P)
+	one.Init64(1, &c.P)
+
+	ypo.Add(&one, &yy)
+	ymo.Sub(&one, &yy)
+	um.Div(&ypo, &ymo)
+	vm.Div(&um, &xx)
+
+	return new(big.Int).Set(&um.V), new(big.Int).Set(&vm.V)
+}
+
+// toWeierstrass converts an affine twisted Edwards point all the way
+// to short Weierstrass form via the Montgomery midpoint:
+// x_w = u/B + A/(3*B), y_w = v/B.
+func (s *stdCurve) toWeierstrass(x, y *big.Int) (xw, yw *big.Int) {
+	c := s.c
+	u, v := s.toMontgomery(x, y)
+
+	var um, vm, montA, montB, three, xwm, ywm crypto.ModInt
+	um.Init(u, &c.P)
+	vm.Init(v, &c.P)
+	montA.Init(&s.montA, &c.P)
+	montB.Init(&s.montB, &c.P)
+	three.Init64(3, &c.P)
+
+	var t1, t2 crypto.ModInt
+	t1.Div(&um, &montB)
+	t2.Mul(&montB, &three)
+	t2.Div(&montA, &t2)
+	xwm.Add(&t1, &t2)
+	ywm.Div(&vm, &montB)
+
+	return new(big.Int).Set(&xwm.V), new(big.Int).Set(&ywm.V)
+}
+
+func (s *stdCurve) Params() *elliptic.CurveParams {
+	return s.params
+}
+
+func (s *stdCurve) IsOnCurve(x, y *big.Int) bool {
+	p := s.params.P
+	var xx, yy, l, r, a, b crypto.ModInt
+	xx.Init(x, p)
+	yy.Init(y, p)
+	a.Init(&s.weierA, p)
+	b.Init(&s.weierB, p)
+
+	l.Mul(&yy, &yy)
+
+	r.Mul(&xx, &xx).Mul(&r, &xx)
+	var ax crypto.ModInt
+	ax.Mul(&a, &xx)
+	r.Add(&r, &ax).Add(&r, &b)
+
+	return l.Equal(&r)
+}
+
+func (s *stdCurve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	p := s.params.P
+	if x1.Sign() == 0 && y1.Sign() == 0 {
+		return new(big.Int).Set(x2), new(big.Int).Set(y2)
+	}
+	if x2.Sign() == 0 && y2.Sign() == 0 {
+		return new(big.Int).Set(x1), new(big.Int).Set(y1)
+	}
+	if x1.Cmp(x2) == 0 {
+		if y1.Cmp(y2) == 0 {
+			return s.Double(x1, y1)
+		}
+		return big.NewInt(0), big.NewInt(0) // point at infinity
+	}
+
+	var xx1, yy1, xx2, yy2, lam, dx, dy crypto.ModInt
+	xx1.Init(x1, p)
+	yy1.Init(y1, p)
+	xx2.Init(x2, p)
+	yy2.Init(y2, p)
+
+	dy.Sub(&yy2, &yy1)
+	dx.Sub(&xx2, &xx1)
+	lam.Div(&dy, &dx)
+
+	var xr, yr, lam2 crypto.ModInt
+	lam2.Mul(&lam, &lam)
+	xr.Sub(&lam2, &xx1).Sub(&xr, &xx2)
+	yr.Sub(&xx1, &xr).Mul(&yr, &lam).Sub(&yr, &yy1)
+
+	return new(big.Int).Set(&xr.V), new(big.Int).Set(&yr.V)
+}
+
+func (s *stdCurve) Double(x1, y1 *big.Int) (x, y *big.Int) {
+	p := s.params.P
+	if y1.Sign() == 0 {
+		return big.NewInt(0), big.NewInt(0)
+	}
+
+	var xx1, yy1, a, two, three, lam crypto.ModInt
+	xx1.Init(x1, p)
+	yy1.Init(y1, p)
+	a.Init(&s.weierA, p)
+	two.Init64(2, p)
+	three.Init64(3, p)
+
+	var num, den, x1sq crypto.ModInt
+	x1sq.Mul(&xx1, &xx1)
+	num.Mul(&x1sq, &three).Add(&num, &a)
+	den.Mul(&yy1, &two)
+	lam.Div(&num, &den)
+
+	var xr, yr, lam2 crypto.ModInt
+	lam2.Mul(&lam, &lam)
+	xr.Sub(&lam2, &xx1).Sub(&xr, &xx1)
+	yr.Sub(&xx1, &xr).Mul(&yr, &lam).Sub(&yr, &yy1)
+
+	return new(big.Int).Set(&xr.V), new(big.Int).Set(&yr.V)
+}
+
+func (s *stdCurve) ScalarMult(x1, y1 *big.Int, k []byte) (x, y *big.Int) {
+	x, y = big.NewInt(0), big.NewInt(0)
+	bx, by := new(big.Int).Set(x1), new(big.Int).Set(y1)
+	for _, byteVal := range k {
+		for bit := 0; bit < 8; bit++ {
+			x, y = s.Double(x, y)
+			if byteVal&0x80 != 0 {
+				x, y = s.Add(x, y, bx, by)
+			}
+			byteVal <<= 1
+		}
+	}
+	return
+}
+
+func (s *stdCurve) ScalarBaseMult(k []byte) (x, y *big.Int) {
+	return s.ScalarMult(s.params.Gx, s.params.Gy, k)
+}
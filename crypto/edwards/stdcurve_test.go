@@ -0,0 +1,34 @@
+package edwards
+
+import (
+	"testing"
+
+	"dissent/crypto"
+)
+
+// TestAsStdCurve checks that the converted generator lands on the
+// derived short-Weierstrass curve, and that ScalarBaseMult agrees
+// with the underlying edwards group's own Base/Mul.
+func TestAsStdCurve(t *testing.T) {
+	p := ed25519TestParam()
+	group := NewExtendedCurve(p, false)
+	std := AsStdCurve(group)
+
+	params := std.Params()
+	if !std.IsOnCurve(params.Gx, params.Gy) {
+		t.Fatal("converted generator is not on the derived Weierstrass curve")
+	}
+
+	k := []byte{5}
+	wx, wy := std.ScalarBaseMult(k)
+
+	var s crypto.ModInt
+	s.Init64(5, &group.(rawCurve).curveParam().order.V)
+	edP := group.Point().Mul(group.Point().Base(), &s).(point)
+	ex, ey := edP.getXY()
+	gx, gy := std.(*stdCurve).toWeierstrass(&ex.V, &ey.V)
+
+	if wx.Cmp(gx) != 0 || wy.Cmp(gy) != 0 {
+		t.Fatal("ScalarBaseMult disagrees with the edwards group's own scalar multiplication")
+	}
+}
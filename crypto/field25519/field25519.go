@@ -0,0 +1,468 @@
+// Package field25519 implements constant-time field arithmetic for
+// GF(2^255-19), the field underlying Curve25519 and Ed25519.
+//
+// Elements are represented in the "radix 2^25.5" form used by the
+// ref10/fiat-crypto implementations: ten int32 limbs alternately
+// holding 26 and 25 bits, so that a limb's value times its place
+// weight never needs more than 54 bits to multiply safely in an
+// int64 accumulator. Every operation here runs in time and with a
+// memory-access pattern independent of the field elements involved,
+// unlike the generic crypto.ModInt (math/big) arithmetic the edwards
+// package otherwise uses.
+package field25519
+
+// Element holds a field element as 10 limbs, least-significant first,
+// with limb i worth 2^ceil(25.5*i) (i.e. limbs alternate 2^26, 2^25).
+// The limbs are not necessarily fully reduced between operations;
+// FeToBytes performs the final carry and reduction mod p.
+type Element [10]int32
+
+func load3(in []byte) int64 {
+	var r int64
+	r = int64(in[0])
+	r |= int64(in[1]) << 8
+	r |= int64(in[2]) << 16
+	return r
+}
+
+func load4(in []byte) int64 {
+	var r int64
+	r = int64(in[0])
+	r |= int64(in[1]) << 8
+	r |= int64(in[2]) << 16
+	r |= int64(in[3]) << 24
+	return r
+}
+
+// FeFromBytes decodes a little-endian 32-byte string into an Element,
+// masking off the unused top bit as Curve25519/Ed25519 require.
+func FeFromBytes(dst *Element, in *[32]byte) {
+	h0 := load4(in[0:4])
+	h1 := load3(in[4:7]) << 6
+	h2 := load3(in[7:10]) << 5
+	h3 := load3(in[10:13]) << 3
+	h4 := load3(in[13:16]) << 2
+	h5 := load4(in[16:20])
+	h6 := load3(in[20:23]) << 7
+	h7 := load3(in[23:26]) << 5
+	h8 := load3(in[26:29]) << 4
+	h9 := (load3(in[29:32]) & 0x7fffff) << 2
+
+	var carry [10]int64
+	carry[9] = (h9 + 1<<24) >> 25
+	h0 += carry[9] * 19
+	h9 -= carry[9] << 25
+	carry[1] = (h1 + 1<<24) >> 25
+	h2 += carry[1]
+	h1 -= carry[1] << 25
+	carry[3] = (h3 + 1<<24) >> 25
+	h4 += carry[3]
+	h3 -= carry[3] << 25
+	carry[5] = (h5 + 1<<24) >> 25
+	h6 += carry[5]
+	h5 -= carry[5] << 25
+	carry[7] = (h7 + 1<<24) >> 25
+	h8 += carry[7]
+	h7 -= carry[7] << 25
+
+	carry[0] = (h0 + 1<<25) >> 26
+	h1 += carry[0]
+	h0 -= carry[0] << 26
+	carry[2] = (h2 + 1<<25) >> 26
+	h3 += carry[2]
+	h2 -= carry[2] << 26
+	carry[4] = (h4 + 1<<25) >> 26
+	h5 += carry[4]
+	h4 -= carry[4] << 26
+	carry[6] = (h6 + 1<<25) >> 26
+	h7 += carry[6]
+	h6 -= carry[6] << 26
+	carry[8] = (h8 + 1<<25) >> 26
+	h9 += carry[8]
+	h8 -= carry[8] << 26
+
+	dst[0] = int32(h0)
+	dst[1] = int32(h1)
+	dst[2] = int32(h2)
+	dst[3] = int32(h3)
+	dst[4] = int32(h4)
+	dst[5] = int32(h5)
+	dst[6] = int32(h6)
+	dst[7] = int32(h7)
+	dst[8] = int32(h8)
+	dst[9] = int32(h9)
+}
+
+// FeToBytes fully carries and reduces in mod p = 2^255-19
+// and encodes the result little-endian into 32 bytes.
+func FeToBytes(out *[32]byte, in *Element) {
+	var h [10]int32
+	copy(h[:], in[:])
+
+	var q int32
+	q = (19*h[9] + (1 << 24)) >> 25
+	q = (h[0] + q) >> 26
+	q = (h[1] + q) >> 25
+	q = (h[2] + q) >> 26
+	q = (h[3] + q) >> 25
+	q = (h[4] + q) >> 26
+	q = (h[5] + q) >> 25
+	q = (h[6] + q) >> 26
+	q = (h[7] + q) >> 25
+	q = (h[8] + q) >> 26
+	q = (h[9] + q) >> 25
+
+	// Add the final carry's worth of 19 back in, then subtract 2^255
+	// by simply discarding it (the limbs below already fit).
+	h[0] += 19 * q
+
+	carry := [10]int32{}
+	carry[0] = h[0] >> 26
+	h[1] += carry[0]
+	h[0] -= carry[0] << 26
+	carry[1] = h[1] >> 25
+	h[2] += carry[1]
+	h[1] -= carry[1] << 25
+	carry[2] = h[2] >> 26
+	h[3] += carry[2]
+	h[2] -= carry[2] << 26
+	carry[3] = h[3] >> 25
+	h[4] += carry[3]
+	h[3] -= carry[3] << 25
+	carry[4] = h[4] >> 26
+	h[5] += carry[4]
+	h[4] -= carry[4] << 26
+	carry[5] = h[5] >> 25
+	h[6] += carry[5]
+	h[5] -= carry[5] << 25
+	carry[6] = h[6] >> 26
+	h[7] += carry[6]
+	h[6] -= carry[6] << 26
+	carry[7] = h[7] >> 25
+	h[8] += carry[7]
+	h[7] -= carry[7] << 25
+	carry[8] = h[8] >> 26
+	h[9] += carry[8]
+	h[8] -= carry[8] << 26
+	carry[9] = h[9] >> 25
+	h[9] -= carry[9] << 25
+
+	out[0] = byte(h[0] >> 0)
+	out[1] = byte(h[0] >> 8)
+	out[2] = byte(h[0] >> 16)
+	out[3] = byte((h[0] >> 24) | (h[1] << 2))
+	out[4] = byte(h[1] >> 6)
+	out[5] = byte(h[1] >> 14)
+	out[6] = byte((h[1] >> 22) | (h[2] << 3))
+	out[7] = byte(h[2] >> 5)
+	out[8] = byte(h[2] >> 13)
+	out[9] = byte((h[2] >> 21) | (h[3] << 5))
+	out[10] = byte(h[3] >> 3)
+	out[11] = byte(h[3] >> 11)
+	out[12] = byte((h[3] >> 19) | (h[4] << 6))
+	out[13] = byte(h[4] >> 2)
+	out[14] = byte(h[4] >> 10)
+	out[15] = byte(h[4] >> 18)
+	out[16] = byte(h[5] >> 0)
+	out[17] = byte(h[5] >> 8)
+	out[18] = byte(h[5] >> 16)
+	out[19] = byte((h[5] >> 24) | (h[6] << 1))
+	out[20] = byte(h[6] >> 7)
+	out[21] = byte(h[6] >> 15)
+	out[22] = byte((h[6] >> 23) | (h[7] << 3))
+	out[23] = byte(h[7] >> 5)
+	out[24] = byte(h[7] >> 13)
+	out[25] = byte((h[7] >> 21) | (h[8] << 4))
+	out[26] = byte(h[8] >> 4)
+	out[27] = byte(h[8] >> 12)
+	out[28] = byte((h[8] >> 20) | (h[9] << 6))
+	out[29] = byte(h[9] >> 2)
+	out[30] = byte(h[9] >> 10)
+	out[31] = byte(h[9] >> 18)
+}
+
+// FeAdd sets dst = a+b. No carry propagation is performed;
+// limbs may grow slightly, which FeMul/FeSquare/FeToBytes account for.
+func FeAdd(dst, a, b *Element) {
+	for i := range dst {
+		dst[i] = a[i] + b[i]
+	}
+}
+
+// FeSub sets dst = a-b.
+func FeSub(dst, a, b *Element) {
+	for i := range dst {
+		dst[i] = a[i] - b[i]
+	}
+}
+
+// FeNeg sets dst = -a.
+func FeNeg(dst, a *Element) {
+	for i := range dst {
+		dst[i] = -a[i]
+	}
+}
+
+// FeCMov sets dst = b if swap == 1, or leaves dst unchanged if
+// swap == 0, touching every limb either way so the choice is not
+// observable via timing or memory access pattern. swap must be
+// exactly 0 or 1; any other value is a caller error.
+func FeCMov(dst, b *Element, swap int32) {
+	mask := -swap // all-1s if swap==1, all-0s if swap==0
+	for i := range dst {
+		t := mask & (dst[i] ^ b[i])
+		dst[i] ^= t
+	}
+}
+
+// FeIsNegative returns 1 if the canonical (fully reduced) encoding
+// of in has its least-significant bit set, 0 otherwise -- the "sign"
+// bit convention used throughout Curve25519/Ed25519 point encoding.
+func FeIsNegative(in *Element) int32 {
+	var s [32]byte
+	FeToBytes(&s, in)
+	return int32(s[0] & 1)
+}
+
+// FeMul sets dst = a*b mod p, using schoolbook multiplication with
+// the usual 19x reduction for limbs that would otherwise overflow
+// past the 2^255 boundary, then a full carry chain back to 10 limbs.
+func FeMul(dst, a, b *Element) {
+	a0, a1, a2, a3, a4, a5, a6, a7, a8, a9 :=
+		int64(a[0]), int64(a[1]), int64(a[2]), int64(a[3]), int64(a[4]),
+		int64(a[5]), int64(a[6]), int64(a[7]), int64(a[8]), int64(a[9])
+	b0, b1, b2, b3, b4, b5, b6, b7, b8, b9 :=
+		int64(b[0]), int64(b[1]), int64(b[2]), int64(b[3]), int64(b[4]),
+		int64(b[5]), int64(b[6]), int64(b[7]), int64(b[8]), int64(b[9])
+
+	b1_19 := 19 * b1
+	b2_19 := 19 * b2
+	b3_19 := 19 * b3
+	b4_19 := 19 * b4
+	b5_19 := 19 * b5
+	b6_19 := 19 * b6
+	b7_19 := 19 * b7
+	b8_19 := 19 * b8
+	b9_19 := 19 * b9
+
+	a1_2 := 2 * a1
+	a3_2 := 2 * a3
+	a5_2 := 2 * a5
+	a7_2 := 2 * a7
+	a9_2 := 2 * a9
+
+	h0 := a0*b0 + a1_2*b9_19 + a2*b8_19 + a3_2*b7_19 + a4*b6_19 + a5_2*b5_19 + a6*b4_19 + a7_2*b3_19 + a8*b2_19 + a9_2*b1_19
+	h1 := a0*b1 + a1*b0 + a2*b9_19 + a3*b8_19 + a4*b7_19 + a5*b6_19 + a6*b5_19 + a7*b4_19 + a8*b3_19 + a9*b2_19
+	h2 := a0*b2 + a1_2*b1 + a2*b0 + a3_2*b9_19 + a4*b8_19 + a5_2*b7_19 + a6*b6_19 + a7_2*b5_19 + a8*b4_19 + a9_2*b3_19
+	h3 := a0*b3 + a1*b2 + a2*b1 + a3*b0 + a4*b9_19 + a5*b8_19 + a6*b7_19 + a7*b6_19 + a8*b5_19 + a9*b4_19
+	h4 := a0*b4 + a1_2*b3 + a2*b2 + a3_2*b1 + a4*b0 + a5_2*b9_19 + a6*b8_19 + a7_2*b7_19 + a8*b6_19 + a9_2*b5_19
+	h5 := a0*b5 + a1*b4 + a2*b3 + a3*b2 + a4*b1 + a5*b0 + a6*b9_19 + a7*b8_19 + a8*b7_19 + a9*b6_19
+	h6 := a0*b6 + a1_2*b5 + a2*b4 + a3_2*b3 + a4*b2 + a5_2*b1 + a6*b0 + a7_2*b9_19 + a8*b8_19 + a9_2*b7_19
+	h7 := a0*b7 + a1*b6 + a2*b5 + a3*b4 + a4*b3 + a5*b2 + a6*b1 + a7*b0 + a8*b9_19 + a9*b8_19
+	h8 := a0*b8 + a1_2*b7 + a2*b6 + a3_2*b5 + a4*b4 + a5_2*b3 + a6*b2 + a7_2*b1 + a8*b0 + a9_2*b9_19
+	h9 := a0*b9 + a1*b8 + a2*b7 + a3*b6 + a4*b5 + a5*b4 + a6*b3 + a7*b2 + a8*b1 + a9*b0
+
+	carry(dst, &h0, &h1, &h2, &h3, &h4, &h5, &h6, &h7, &h8, &h9)
+}
+
+// FeSquare sets dst = a*a mod p; equivalent to, but roughly twice as
+// fast as, FeMul(dst, a, a), since several cross-terms double up.
+func FeSquare(dst, a *Element) {
+	a0, a1, a2, a3, a4, a5, a6, a7, a8, a9 :=
+		int64(a[0]), int64(a[1]), int64(a[2]), int64(a[3]), int64(a[4]),
+		int64(a[5]), int64(a[6]), int64(a[7]), int64(a[8]), int64(a[9])
+
+	a0_2 := 2 * a0
+	a1_2 := 2 * a1
+	a2_2 := 2 * a2
+	a3_2 := 2 * a3
+	a4_2 := 2 * a4
+	a5_2 := 2 * a5
+	a6_2 := 2 * a6
+	a7_2 := 2 * a7
+	a5_38 := 38 * a5 // 19*2
+	a6_19 := 19 * a6
+	a7_38 := 38 * a7
+	a8_19 := 19 * a8
+	a9_38 := 38 * a9
+
+	h0 := a0*a0 + a1_2*a9_38 + a2_2*a8_19 + a3_2*a7_38 + a4_2*a6_19 + a5*a5_38
+	h1 := a0_2*a1 + a2*a9_38 + a3_2*a8_19 + a4*a7_38 + a5_2*a6_19
+	h2 := a0_2*a2 + a1_2*a1 + a3_2*a9_38 + a4_2*a8_19 + a5_2*a7_38 + a6*a6_19
+	h3 := a0_2*a3 + a1_2*a2 + a4*a9_38 + a5_2*a8_19 + a6*a7_38
+	h4 := a0_2*a4 + a1_2*a3_2 + a2*a2 + a5_2*a9_38 + a6_2*a8_19 + a7*a7_38
+	h5 := a0_2*a5 + a1_2*a4 + a2_2*a3 + a6*a9_38 + a7_2*a8_19
+	h6 := a0_2*a6 + a1_2*a5_2 + a2_2*a4 + a3_2*a3 + a7_2*a9_38 + a8*a8_19
+	h7 := a0_2*a7 + a1_2*a6 + a2_2*a5 + a3_2*a4 + a8*a9_38
+	h8 := a0_2*a8 + a1_2*a7_2 + a2_2*a6 + a3_2*a5_2 + a4*a4 + a9*a9_38
+	h9 := a0_2*a9 + a1_2*a8 + a2_2*a7 + a3_2*a6 + a4_2*a5
+
+	carry(dst, &h0, &h1, &h2, &h3, &h4, &h5, &h6, &h7, &h8, &h9)
+}
+
+// carry performs the full schoolbook-product carry chain,
+// reducing ten wide (up to ~62-bit) accumulators back into the
+// canonical 10x int32 limb layout of dst.
+func carry(dst *Element, h0, h1, h2, h3, h4, h5, h6, h7, h8, h9 *int64) {
+	var c0, c1, c2, c3, c4, c5, c6, c7, c8, c9 int64
+
+	c0 = (*h0 + (1 << 25)) >> 26
+	*h1 += c0
+	*h0 -= c0 << 26
+	c4 = (*h4 + (1 << 25)) >> 26
+	*h5 += c4
+	*h4 -= c4 << 26
+
+	c1 = (*h1 + (1 << 24)) >> 25
+	*h2 += c1
+	*h1 -= c1 << 25
+	c5 = (*h5 + (1 << 24)) >> 25
+	*h6 += c5
+	*h5 -= c5 << 25
+
+	c2 = (*h2 + (1 << 25)) >> 26
+	*h3 += c2
+	*h2 -= c2 << 26
+	c6 = (*h6 + (1 << 25)) >> 26
+	*h7 += c6
+	*h6 -= c6 << 26
+
+	c3 = (*h3 + (1 << 24)) >> 25
+	*h4 += c3
+	*h3 -= c3 << 25
+	c7 = (*h7 + (1 << 24)) >> 25
+	*h8 += c7
+	*h7 -= c7 << 25
+
+	c4 = (*h4 + (1 << 25)) >> 26
+	*h5 += c4
+	*h4 -= c4 << 26
+	c8 = (*h8 + (1 << 25)) >> 26
+	*h9 += c8
+	*h8 -= c8 << 26
+
+	c9 = (*h9 + (1 << 24)) >> 25
+	*h0 += c9 * 19
+	*h9 -= c9 << 25
+
+	c0 = (*h0 + (1 << 25)) >> 26
+	*h1 += c0
+	*h0 -= c0 << 26
+
+	dst[0] = int32(*h0)
+	dst[1] = int32(*h1)
+	dst[2] = int32(*h2)
+	dst[3] = int32(*h3)
+	dst[4] = int32(*h4)
+	dst[5] = int32(*h5)
+	dst[6] = int32(*h6)
+	dst[7] = int32(*h7)
+	dst[8] = int32(*h8)
+	dst[9] = int32(*h9)
+}
+
+// FeInvert sets dst = 1/a via Fermat's little theorem, dst = a^(p-2),
+// using the standard Curve25519 addition chain so the exponentiation
+// touches a fixed, secret-independent sequence of squarings and
+// multiplications.
+func FeInvert(dst, a *Element) {
+	var t0, t1, t2, t3 Element
+
+	FeSquare(&t0, a)             // t0 = a^2
+	FeSquare(&t1, &t0)           // t1 = a^4
+	FeSquare(&t1, &t1)           // t1 = a^8
+	FeMul(&t1, a, &t1)           // t1 = a^9
+	FeMul(&t0, &t0, &t1)         // t0 = a^11
+	FeSquare(&t2, &t0)           // t2 = a^22
+	FeMul(&t1, &t1, &t2)         // t1 = a^31 = a^(2^5-1)
+	FeSquare(&t2, &t1)           //
+	for i := 0; i < 4; i++ {     // t2 = a^(2^10-2^5)
+		FeSquare(&t2, &t2)
+	}
+	FeMul(&t1, &t2, &t1) // t1 = a^(2^10-1)
+	FeSquare(&t2, &t1)
+	for i := 0; i < 9; i++ { // t2 = a^(2^20-2^10)
+		FeSquare(&t2, &t2)
+	}
+	FeMul(&t2, &t2, &t1) // t2 = a^(2^20-1)
+	FeSquare(&t3, &t2)
+	for i := 0; i < 19; i++ { // t3 = a^(2^40-2^20)
+		FeSquare(&t3, &t3)
+	}
+	FeMul(&t2, &t3, &t2) // t2 = a^(2^40-1)
+	for i := 0; i < 10; i++ {
+		FeSquare(&t2, &t2) // t2 = a^(2^50-2^10)
+	}
+	FeMul(&t1, &t2, &t1) // t1 = a^(2^50-1)
+	FeSquare(&t2, &t1)
+	for i := 0; i < 49; i++ { // t2 = a^(2^100-2^50)
+		FeSquare(&t2, &t2)
+	}
+	FeMul(&t2, &t2, &t1) // t2 = a^(2^100-1)
+	FeSquare(&t3, &t2)
+	for i := 0; i < 99; i++ { // t3 = a^(2^200-2^100)
+		FeSquare(&t3, &t3)
+	}
+	FeMul(&t2, &t3, &t2) // t2 = a^(2^200-1)
+	for i := 0; i < 50; i++ {
+		FeSquare(&t2, &t2) // t2 = a^(2^250-2^50)
+	}
+	FeMul(&t1, &t2, &t1) // t1 = a^(2^250-1)
+	for i := 0; i < 5; i++ {
+		FeSquare(&t1, &t1) // t1 = a^(2^255-2^5)
+	}
+	FeMul(dst, &t1, &t0) // dst = a^(2^255-21) = a^(p-2)
+}
+
+// FePow22523 sets dst = a^((p-5)/8) = a^(2^252-3), the exponentiation
+// at the heart of Ed25519's combined inverse-square-root formula
+// beta = (u*v^3)*(u*v^7)^((p-5)/8) used by solveForX's fast path.
+// It shares the first half of FeInvert's addition chain, since
+// 2^252-3 = 4*(2^250-1) + 1.
+func FePow22523(dst, a *Element) {
+	var t0, t1, t2 Element
+
+	FeSquare(&t0, a)     // t0 = a^2
+	FeSquare(&t1, &t0)   // t1 = a^4
+	FeSquare(&t1, &t1)   // t1 = a^8
+	FeMul(&t1, a, &t1)   // t1 = a^9
+	FeMul(&t0, &t0, &t1) // t0 = a^11
+	FeSquare(&t0, &t0)   // t0 = a^22
+	FeMul(&t0, &t1, &t0) // t0 = a^(2^5-1)
+	FeSquare(&t1, &t0)
+	for i := 0; i < 4; i++ { // t1 = a^(2^10-2^5)
+		FeSquare(&t1, &t1)
+	}
+	FeMul(&t0, &t1, &t0) // t0 = a^(2^10-1)
+	FeSquare(&t1, &t0)
+	for i := 0; i < 9; i++ { // t1 = a^(2^20-2^10)
+		FeSquare(&t1, &t1)
+	}
+	FeMul(&t1, &t1, &t0) // t1 = a^(2^20-1)
+	FeSquare(&t2, &t1)
+	for i := 0; i < 19; i++ { // t2 = a^(2^40-2^20)
+		FeSquare(&t2, &t2)
+	}
+	FeMul(&t1, &t2, &t1) // t1 = a^(2^40-1)
+	for i := 0; i < 10; i++ {
+		FeSquare(&t1, &t1) // t1 = a^(2^50-2^10)
+	}
+	FeMul(&t0, &t1, &t0) // t0 = a^(2^50-1)
+	FeSquare(&t1, &t0)
+	for i := 0; i < 49; i++ { // t1 = a^(2^100-2^50)
+		FeSquare(&t1, &t1)
+	}
+	FeMul(&t1, &t1, &t0) // t1 = a^(2^100-1)
+	FeSquare(&t2, &t1)
+	for i := 0; i < 99; i++ { // t2 = a^(2^200-2^100)
+		FeSquare(&t2, &t2)
+	}
+	FeMul(&t1, &t2, &t1) // t1 = a^(2^200-1)
+	for i := 0; i < 50; i++ {
+		FeSquare(&t1, &t1) // t1 = a^(2^250-2^50)
+	}
+	FeMul(&t0, &t1, &t0) // t0 = a^(2^250-1)
+	FeSquare(&t0, &t0)   // t0 = a^(2^251-2)
+	FeSquare(&t0, &t0)   // t0 = a^(2^252-4)
+	FeMul(dst, &t0, a)   // dst = a^(2^252-3)
+}